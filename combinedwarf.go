@@ -0,0 +1,122 @@
+package macho
+
+import "fmt"
+
+// ErrNoLoadCommandPadding is returned by InsertDWARFFrom when there isn't
+// enough zero padding between the end of the existing load commands and the
+// file offset of the first real segment to fit the new __DWARF LC_SEGMENT_64
+// plus its section headers.
+type ErrNoLoadCommandPadding struct {
+	Need uint32
+	Have uint32
+}
+
+func (e ErrNoLoadCommandPadding) Error() string {
+	return fmt.Sprintf("macho: not enough load command padding to insert __DWARF segment: need %d bytes, have %d", e.Need, e.Have)
+}
+
+// InsertDWARFFrom splices the __DWARF segment and its sections from dsym
+// into t's load commands, in the manner of the classic macho_combine_dwarf
+// tool, so that t becomes a standalone binary carrying its own debug info.
+// dsymPayload must be the fully serialized bytes of dsym; the spliced
+// section bytes are sliced directly out of it and queued so that a
+// subsequent t.Put writes them out immediately after t's existing content.
+func (t *FileTOC) InsertDWARFFrom(dsym *FileTOC, dsymPayload []byte) error {
+	var dwarfSeg *Segment
+	for _, l := range dsym.Loads {
+		if seg, ok := l.(*Segment); ok && seg.Name == "__DWARF" {
+			dwarfSeg = seg
+			break
+		}
+	}
+	if dwarfSeg == nil {
+		return fmt.Errorf("macho: dsym has no __DWARF segment")
+	}
+
+	// firstDataOffset is the file offset of the first actual section's
+	// bytes (e.g. __text), not the segment's own Offset field: __TEXT
+	// conventionally sets Offset to 0, since it contains the header
+	// itself (see the seg.Offset != 0 special case in Validate), so using
+	// the segment offset here would leave have at 0 below and reject the
+	// most common executable layout outright.
+	firstDataOffset := t.FileSize()
+	for _, s := range t.Sections {
+		if s.Size == 0 {
+			continue
+		}
+		if o := uint64(s.Offset); o < firstDataOffset {
+			firstDataOffset = o
+		}
+	}
+
+	// need only has to cover the new LC_SEGMENT_64 plus its section
+	// headers: the gap between the end of the existing load commands and
+	// the first real segment is load-command padding, never big enough to
+	// hold the DWARF payload itself — that goes after t's existing content
+	// instead (see dataOff below).
+	need := uint64(segmentCommandSize(t.Magic)) + uint64(dwarfSeg.Nsect)*uint64(sectionWireSize(t.Magic))
+	toc := uint64(t.TOCSize())
+	var have uint64
+	if firstDataOffset > toc {
+		have = firstDataOffset - toc
+	}
+	if need > have {
+		return ErrNoLoadCommandPadding{Need: uint32(need), Have: uint32(have)}
+	}
+
+	// Capture the base offset before any AddSegment/AddSection call below,
+	// since those mutate t and would otherwise make a post-call FileSize()
+	// reflect the segment we're still in the middle of inserting.
+	dataOff := t.FileSize()
+
+	newSeg := &Segment{SegmentHeader: dwarfSeg.SegmentHeader}
+	newSeg.Offset = dataOff
+	t.AddSegment(newSeg)
+	if err := t.Err(); err != nil {
+		return fmt.Errorf("macho: adding __DWARF segment: %w", err)
+	}
+
+	var combined []byte
+	written := uint64(0)
+	for i := uint32(0); i < dwarfSeg.Nsect; i++ {
+		orig := *dsym.Sections[dwarfSeg.Firstsect+i]
+		data := dsymPayload[orig.Offset : uint64(orig.Offset)+orig.Size]
+		if orig.Compressed {
+			orig.Data = data
+			if err := orig.Uncompress(); err != nil {
+				return fmt.Errorf("macho: decompressing %s: %w", orig.Name, err)
+			}
+			data = orig.Data
+		}
+
+		sec := orig
+		sec.Addr = newSeg.Addr + written
+		sec.Offset = uint32(dataOff + written)
+		sec.Compressed = false
+		t.AddSection(&sec)
+		if err := t.Err(); err != nil {
+			return fmt.Errorf("macho: adding %s: %w", sec.Name, err)
+		}
+
+		combined = append(combined, data...)
+		written += uint64(len(data))
+	}
+	newSeg.Filesz = written
+	newSeg.Memsz = written
+	t.trailing.offset = dataOff
+	t.trailing.data = combined
+
+	for _, l := range dsym.Loads {
+		if dsymSyms, ok := l.(*Symtab); ok {
+			for _, tl := range t.Loads {
+				if syms, ok := tl.(*Symtab); ok {
+					syms.Syms = append(syms.Syms, dsymSyms.Syms...)
+					break
+				}
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package macho
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blacktop/go-macho/types"
+)
+
+// dwarfDonor builds a minimal dsym-shaped FileTOC with a __DWARF segment
+// holding two sections, plus the serialized payload those sections' Offset/
+// Size fields point into. InsertDWARFFrom only ever slices dsymPayload by
+// Offset/Size, so the payload doesn't need to be dsym.Put's own output.
+func dwarfDonor(t *testing.T) (dsym *FileTOC, payload []byte) {
+	t.Helper()
+
+	info := []byte("debug-info-bytes")
+	abbrev := []byte("debug-abbrev-bytes")
+	payload = append(append([]byte{}, info...), abbrev...)
+
+	dsym = &FileTOC{}
+	dsym.Magic = types.Magic64
+
+	seg := &Segment{SegmentHeader: types.SegmentHeader{Name: "__DWARF"}}
+	dsym.AddSegment(seg)
+	if err := dsym.Err(); err != nil {
+		t.Fatalf("AddSegment(__DWARF): %v", err)
+	}
+
+	dsym.AddSection(&types.Section{Name: "__debug_info", Seg: "__DWARF", Offset: 0, Size: uint64(len(info))})
+	dsym.AddSection(&types.Section{Name: "__debug_abbrev", Seg: "__DWARF", Offset: uint32(len(info)), Size: uint64(len(abbrev))})
+	if err := dsym.Err(); err != nil {
+		t.Fatalf("AddSection: %v", err)
+	}
+
+	return dsym, payload
+}
+
+// textTarget builds a minimal executable-shaped FileTOC with a __TEXT
+// segment at the conventional Offset 0, whose __text section's Offset sits
+// padAfterTOC bytes past TOCSize() — the gap InsertDWARFFrom must check
+// before splicing in a new __DWARF segment.
+func textTarget(t *testing.T, padAfterTOC uint32) *FileTOC {
+	t.Helper()
+
+	tc := &FileTOC{}
+	tc.Magic = types.Magic64
+
+	textSeg := &Segment{SegmentHeader: types.SegmentHeader{Name: "__TEXT"}}
+	tc.AddSegment(textSeg)
+	textSec := &types.Section{Name: "__text", Seg: "__TEXT", Size: 0x100}
+	tc.AddSection(textSec)
+	if err := tc.Err(); err != nil {
+		t.Fatalf("building target __TEXT: %v", err)
+	}
+	// __TEXT conventionally starts at file offset 0, since it contains the
+	// header itself; only its section has a real, nonzero file offset.
+	textSeg.Offset = 0
+	textSec.Offset = uint32(tc.TOCSize()) + padAfterTOC
+	// As in a real executable, __TEXT's file extent covers its __text.
+	textSeg.Filesz = uint64(textSec.Offset) + textSec.Size
+
+	return tc
+}
+
+func TestErrNoLoadCommandPaddingMessage(t *testing.T) {
+	err := ErrNoLoadCommandPadding{Need: 100, Have: 40}
+	var target ErrNoLoadCommandPadding
+	if !errors.As(err, &target) {
+		t.Fatalf("errors.As failed to match ErrNoLoadCommandPadding")
+	}
+	if target.Need != 100 || target.Have != 40 {
+		t.Errorf("got Need=%d Have=%d, want Need=100 Have=40", target.Need, target.Have)
+	}
+	if msg := err.Error(); msg == "" {
+		t.Error("Error() returned an empty string")
+	}
+}
+
+func TestInsertDWARFFromSplicesSectionsWithEnoughPadding(t *testing.T) {
+	dsym, payload := dwarfDonor(t)
+	need := segmentCommandSize(types.Magic64) + 2*sectionWireSize(types.Magic64)
+	tc := textTarget(t, need+64) // plenty of slack past TOCSize
+
+	dataOff := tc.FileSize()
+
+	if err := tc.InsertDWARFFrom(dsym, payload); err != nil {
+		t.Fatalf("InsertDWARFFrom() error = %v", err)
+	}
+
+	last := tc.Loads[len(tc.Loads)-1].(*Segment)
+	if last.Name != "__DWARF" {
+		t.Fatalf("last load = %q, want __DWARF", last.Name)
+	}
+	if last.Nsect != 2 {
+		t.Fatalf("__DWARF Nsect = %d, want 2", last.Nsect)
+	}
+
+	info := tc.Sections[last.Firstsect]
+	abbrev := tc.Sections[last.Firstsect+1]
+	if info.Offset != uint32(dataOff) {
+		t.Errorf("__debug_info Offset = %d, want %d", info.Offset, dataOff)
+	}
+	if abbrev.Offset != uint32(dataOff)+uint32(info.Size) {
+		t.Errorf("__debug_abbrev Offset = %d, want %d", abbrev.Offset, uint32(dataOff)+uint32(info.Size))
+	}
+	if got, want := tc.trailing.data, payload; string(got) != string(want) {
+		t.Errorf("trailing data = %q, want %q", got, want)
+	}
+
+	buf := make([]byte, tc.FileSize())
+	tc.Put(buf)
+	if got := buf[info.Offset : info.Offset+uint32(info.Size)]; string(got) != "debug-info-bytes" {
+		t.Errorf("Put wrote %q at __debug_info.Offset, want %q", got, "debug-info-bytes")
+	}
+}
+
+func TestInsertDWARFFromRejectsInsufficientPadding(t *testing.T) {
+	dsym, payload := dwarfDonor(t)
+	tc := textTarget(t, 1) // far less than segmentCommandSize+2*sectionWireSize
+
+	err := tc.InsertDWARFFrom(dsym, payload)
+	var padErr ErrNoLoadCommandPadding
+	if !errors.As(err, &padErr) {
+		t.Fatalf("InsertDWARFFrom() error = %v, want ErrNoLoadCommandPadding", err)
+	}
+	if want := segmentCommandSize(types.Magic64) + 2*sectionWireSize(types.Magic64); padErr.Need != want {
+		t.Errorf("Need = %d, want %d", padErr.Need, want)
+	}
+}
+
+func TestInsertDWARFFromRejectsZeroOffsetTEXT(t *testing.T) {
+	// __TEXT.Offset == 0 is the conventional layout for an executable:
+	// this must not underflow the have := firstDataOffset - toc
+	// computation into a huge have that defeats the padding check.
+	dsym, payload := dwarfDonor(t)
+	tc := textTarget(t, 0)
+	tc.Sections[0].Offset = 0 // __text itself also flush against the header
+
+	err := tc.InsertDWARFFrom(dsym, payload)
+	var padErr ErrNoLoadCommandPadding
+	if !errors.As(err, &padErr) {
+		t.Fatalf("InsertDWARFFrom() error = %v, want ErrNoLoadCommandPadding", err)
+	}
+	if padErr.Have != 0 {
+		t.Errorf("Have = %d, want 0 (no underflow)", padErr.Have)
+	}
+}
@@ -0,0 +1,127 @@
+package macho
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blacktop/go-macho/types"
+)
+
+const dsymInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleDevelopmentRegion</key>
+	<string>English</string>
+	<key>CFBundleIdentifier</key>
+	<string>com.apple.xcode.dsym.%[1]s</string>
+	<key>CFBundleInfoDictionaryVersion</key>
+	<string>6.0</string>
+	<key>CFBundlePackageType</key>
+	<string>dSYM</string>
+	<key>CFBundleSignature</key>
+	<string>????</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.0</string>
+	<key>CFBundleVersion</key>
+	<string>1</string>
+</dict>
+</plist>
+`
+
+// WriteDSYM derives a companion dSYM bundle from t and writes it out at
+// <path>.dSYM, using the canonical
+// Contents/Resources/DWARF/<basename> layout that lldb and dsymutil expect.
+// payload must be the fully serialized bytes of t (as produced by t.Put
+// plus its section/segment data), since the __DWARF sections are sliced
+// directly out of it.
+func (t *FileTOC) WriteDSYM(path string, payload []byte) error {
+	base := filepath.Base(path)
+	bundle := path + ".dSYM"
+	dwarfDir := filepath.Join(bundle, "Contents", "Resources", "DWARF")
+	if err := os.MkdirAll(dwarfDir, 0o755); err != nil {
+		return fmt.Errorf("macho: creating dSYM bundle: %w", err)
+	}
+
+	dsym := t.DerivedCopy(t.Type, t.Flags)
+
+	for _, l := range t.Loads {
+		if l.Command() == types.LC_UUID {
+			dsym.AddLoad(l)
+		}
+	}
+
+	var chunks [][]byte
+	for _, l := range t.Loads {
+		seg, ok := l.(*Segment)
+		if !ok || seg.Name != "__DWARF" {
+			continue
+		}
+
+		// segOff is where this segment's section data will actually start:
+		// past the TOC as it will be *after* the segment command and all of
+		// its section headers are added below, not the TOCSize() as it
+		// stands right now. AddSegment/AddSection grow SizeCommands by
+		// exactly segmentCommandSize+Nsect*sectionWireSize, so the post-
+		// insert offset can be computed up front instead of re-reading
+		// TOCSize() once the loop (which needs segOff for every iteration)
+		// has already finished.
+		segOff := uint64(dsym.TOCSize()) + uint64(segmentCommandSize(dsym.Magic)) + uint64(seg.Nsect)*uint64(sectionWireSize(dsym.Magic))
+
+		dwarfSeg := &Segment{SegmentHeader: seg.SegmentHeader}
+		dwarfSeg.Addr = 0
+		dwarfSeg.Memsz = seg.Filesz
+		dsym.AddSegment(dwarfSeg)
+		if err := dsym.Err(); err != nil {
+			return fmt.Errorf("macho: adding __DWARF segment: %w", err)
+		}
+
+		written := uint64(0)
+		for i := uint32(0); i < seg.Nsect; i++ {
+			orig := *t.Sections[seg.Firstsect+i]
+			data := payload[orig.Offset : uint64(orig.Offset)+orig.Size]
+			if orig.Compressed {
+				orig.Data = data
+				if err := orig.Uncompress(); err != nil {
+					return fmt.Errorf("macho: decompressing %s: %w", orig.Name, err)
+				}
+				data = orig.Data
+			}
+
+			sec := orig
+			sec.Addr = written
+			sec.Offset = uint32(segOff + written)
+			sec.Compressed = false
+			dsym.AddSection(&sec)
+			if err := dsym.Err(); err != nil {
+				return fmt.Errorf("macho: adding %s: %w", sec.Name, err)
+			}
+
+			chunks = append(chunks, data)
+			written += uint64(len(data))
+		}
+		dwarfSeg.Offset = segOff
+		dwarfSeg.Filesz = written
+		dwarfSeg.Memsz = written
+	}
+
+	buf := make([]byte, dsym.FileSize())
+	dsym.Put(buf)
+	off := dsym.TOCSize()
+	for _, c := range chunks {
+		copy(buf[off:], c)
+		off += uint32(len(c))
+	}
+
+	if err := os.WriteFile(filepath.Join(dwarfDir, base), buf, 0o644); err != nil {
+		return fmt.Errorf("macho: writing dSYM DWARF image: %w", err)
+	}
+
+	plist := fmt.Sprintf(dsymInfoPlist, base)
+	if err := os.WriteFile(filepath.Join(bundle, "Contents", "Info.plist"), []byte(plist), 0o644); err != nil {
+		return fmt.Errorf("macho: writing dSYM Info.plist: %w", err)
+	}
+
+	return nil
+}
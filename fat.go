@@ -0,0 +1,128 @@
+package macho
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/blacktop/go-macho/types"
+)
+
+// fatArchAlignExp is the alignment, expressed as a power-of-two exponent,
+// used for each arch's slice inside a universal binary. 2^14 (16KiB) covers
+// the page size of both arm64 and x86_64, matching what lipo emits.
+const fatArchAlignExp = 14
+
+// FatArchTOC pairs a single-architecture FileTOC with the fully serialized
+// bytes of that arch (as produced by FileTOC.Put plus its section/segment
+// data), ready to be laid out as one slice of a fat binary.
+type FatArchTOC struct {
+	*FileTOC
+	Payload []byte
+
+	// Offset and Size are filled in by NewFatFileTOC once the slice has
+	// been laid out; callers don't need to set them.
+	Offset uint64
+	Size   uint64
+}
+
+// FatFileTOC is the table-of-contents for a universal (fat) Mach-O binary:
+// the multi-arch counterpart to FileTOC.
+type FatFileTOC struct {
+	Magic  types.Magic
+	Arches []FatArchTOC
+}
+
+// NewFatFileTOC lays out a fat binary from a set of per-arch TOCs and their
+// serialized payload bytes, page-aligning each slice's offset. magic must
+// be types.MagicFat or types.MagicFat64.
+func NewFatFileTOC(magic types.Magic, arches []FatArchTOC) *FatFileTOC {
+	ft := &FatFileTOC{Magic: magic, Arches: arches}
+
+	align := uint64(1) << fatArchAlignExp
+	off := uint64(ft.headerSize()) + uint64(len(arches))*uint64(ft.archEntrySize())
+	for i := range ft.Arches {
+		off = (off + align - 1) &^ (align - 1)
+		ft.Arches[i].Offset = off
+		ft.Arches[i].Size = uint64(len(ft.Arches[i].Payload))
+		off += ft.Arches[i].Size
+	}
+
+	return ft
+}
+
+func (ft *FatFileTOC) headerSize() uint32 {
+	return 8 // magic + nfat_arch, both uint32
+}
+
+func (ft *FatFileTOC) archEntrySize() uint32 {
+	if ft.Magic == types.MagicFat64 {
+		return 32 // cputype, cpusubtype, offset, size, align, reserved (4x uint32 + 2x uint64)
+	}
+	return 20 // cputype, cpusubtype, offset, size, align (5x uint32)
+}
+
+// FileSize returns the size in bytes of the fully assembled fat binary,
+// so callers can preallocate a buffer to pass to Put.
+func (ft *FatFileTOC) FileSize() uint64 {
+	if len(ft.Arches) == 0 {
+		return uint64(ft.headerSize())
+	}
+	last := ft.Arches[len(ft.Arches)-1]
+	return last.Offset + last.Size
+}
+
+// Put writes the fat header, fat_arch (or fat_arch_64) table, and every
+// arch's payload bytes to buffer, which must be at least FileSize() long.
+// Fat headers are always big-endian, regardless of the byte order of the
+// individual arch slices.
+func (ft *FatFileTOC) Put(buffer []byte) int {
+	bo := binary.BigEndian
+
+	next := 0
+	bo.PutUint32(buffer[next:], uint32(ft.Magic))
+	next += 4
+	bo.PutUint32(buffer[next:], uint32(len(ft.Arches)))
+	next += 4
+
+	for _, a := range ft.Arches {
+		bo.PutUint32(buffer[next:], uint32(a.FileHeader.CPU))
+		next += 4
+		bo.PutUint32(buffer[next:], uint32(a.FileHeader.SubCPU))
+		next += 4
+		if ft.Magic == types.MagicFat64 {
+			bo.PutUint64(buffer[next:], a.Offset)
+			next += 8
+			bo.PutUint64(buffer[next:], a.Size)
+			next += 8
+			bo.PutUint32(buffer[next:], fatArchAlignExp)
+			next += 4
+			bo.PutUint32(buffer[next:], 0) // reserved
+			next += 4
+		} else {
+			bo.PutUint32(buffer[next:], uint32(a.Offset))
+			next += 4
+			bo.PutUint32(buffer[next:], uint32(a.Size))
+			next += 4
+			bo.PutUint32(buffer[next:], fatArchAlignExp)
+			next += 4
+		}
+	}
+
+	for _, a := range ft.Arches {
+		copy(buffer[a.Offset:], a.Payload)
+	}
+
+	return int(ft.FileSize())
+}
+
+// WriteTo serialises the fat binary and writes it to w.
+func (ft *FatFileTOC) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, ft.FileSize())
+	n := ft.Put(buf)
+	if n != len(buf) {
+		return 0, fmt.Errorf("macho: fat Put wrote %d bytes, expected %d", n, len(buf))
+	}
+	written, err := w.Write(buf)
+	return int64(written), err
+}
@@ -0,0 +1,55 @@
+package macho
+
+import (
+	"testing"
+
+	"github.com/blacktop/go-macho/types"
+)
+
+func TestNewFatFileTOCAlignsAndSizesArches(t *testing.T) {
+	arches := []FatArchTOC{
+		{FileTOC: &FileTOC{FileHeader: types.FileHeader{CPU: types.CPUAmd64}}, Payload: make([]byte, 100)},
+		{FileTOC: &FileTOC{FileHeader: types.FileHeader{CPU: types.CPUArm64}}, Payload: make([]byte, 200)},
+	}
+
+	ft := NewFatFileTOC(types.MagicFat, arches)
+
+	align := uint64(1) << fatArchAlignExp
+	for i, a := range ft.Arches {
+		if a.Offset%align != 0 {
+			t.Errorf("arch %d offset %d not %d-byte aligned", i, a.Offset, align)
+		}
+		if a.Size != uint64(len(arches[i].Payload)) {
+			t.Errorf("arch %d size = %d, want %d", i, a.Size, len(arches[i].Payload))
+		}
+	}
+	if ft.Arches[1].Offset < ft.Arches[0].Offset+ft.Arches[0].Size {
+		t.Errorf("arch 1 offset %d overlaps arch 0's payload (ends at %d)", ft.Arches[1].Offset, ft.Arches[0].Offset+ft.Arches[0].Size)
+	}
+
+	want := ft.Arches[len(ft.Arches)-1].Offset + ft.Arches[len(ft.Arches)-1].Size
+	if got := ft.FileSize(); got != want {
+		t.Errorf("FileSize() = %d, want %d", got, want)
+	}
+}
+
+func TestFatFileTOCPutWritesPayloadsAtTheirOffsets(t *testing.T) {
+	arches := []FatArchTOC{
+		{FileTOC: &FileTOC{FileHeader: types.FileHeader{CPU: types.CPUAmd64}}, Payload: []byte("amd64-payload")},
+		{FileTOC: &FileTOC{FileHeader: types.FileHeader{CPU: types.CPUArm64}}, Payload: []byte("arm64-payload")},
+	}
+	ft := NewFatFileTOC(types.MagicFat, arches)
+
+	buf := make([]byte, ft.FileSize())
+	n := ft.Put(buf)
+	if n != len(buf) {
+		t.Fatalf("Put returned %d, want %d", n, len(buf))
+	}
+
+	for i, a := range ft.Arches {
+		got := buf[a.Offset : a.Offset+a.Size]
+		if string(got) != string(arches[i].Payload) {
+			t.Errorf("arch %d payload at offset %d = %q, want %q", i, a.Offset, got, arches[i].Payload)
+		}
+	}
+}
@@ -9,18 +9,55 @@ import (
 	"github.com/blacktop/go-macho/types"
 )
 
+// Default sanity limits applied by FileTOC.Validate, following the check
+// the Go linker's own Mach-O loader uses to reject implausible headers.
+const (
+	DefaultMaxLoadCommands = 0x10000
+	DefaultMaxSizeCommands = 0x01000000
+)
+
 type FileTOC struct {
 	types.FileHeader
 	ByteOrder binary.ByteOrder
 	Loads     loads
 	Sections  []*types.Section
 	functions []types.Function
+
+	// MaxLoadCommands and MaxSizeCommands bound NCommands and SizeCommands
+	// respectively. They default to DefaultMaxLoadCommands and
+	// DefaultMaxSizeCommands; fuzzers and hardened consumers can tighten
+	// them before calling AddLoad/AddSegment/AddSection or Validate.
+	MaxLoadCommands uint32
+	MaxSizeCommands uint32
+
+	// err records the first error Validate returned from AddLoad/AddSegment/
+	// AddSection, following the sticky-error convention of bufio.Scanner and
+	// hash.Hash. Check it with Err after building up a TOC by hand.
+	err error
+
+	// trailing holds a blob of raw bytes, and the offset it belongs at,
+	// that Put writes out verbatim once the rest of the TOC is in place.
+	// AppendTrailing sets it to TrailingOffset(); InsertDWARFFrom sets it
+	// to the freshly spliced __DWARF segment's own offset.
+	trailing struct {
+		offset uint64
+		data   []byte
+	}
+}
+
+// Err returns the first error recorded by AddLoad/AddSegment/AddSection,
+// or nil if every load added so far has kept the TOC within its limits.
+func (t *FileTOC) Err() error {
+	return t.err
 }
 
 func (t *FileTOC) AddLoad(l Load) {
 	t.Loads = append(t.Loads, l)
 	t.NCommands++
 	t.SizeCommands += l.LoadSize()
+	if t.err == nil {
+		t.err = t.Validate()
+	}
 }
 
 // AddSegment adds segment s to the file table of contents,
@@ -46,6 +83,13 @@ func (t *FileTOC) AddSection(s *types.Section) {
 	}
 	t.SizeCommands += sectionsize
 	g.Len += sectionsize
+	if t.err == nil {
+		if g.Firstsect+g.Nsect > uint32(len(t.Sections)) {
+			t.err = fmt.Errorf("macho: segment %s claims %d sections starting at %d, but only %d sections exist", g.Name, g.Nsect, g.Firstsect, len(t.Sections))
+		} else {
+			t.err = t.Validate()
+		}
+	}
 }
 
 // DerivedCopy returns a modified copy of the TOC, with empty loads and sections,
@@ -120,6 +164,9 @@ func (t *FileTOC) FileSize() uint64 {
 			}
 		}
 	}
+	if m := t.trailing.offset + uint64(len(t.trailing.data)); m > sz {
+		sz = m
+	}
 	return sz
 }
 
@@ -152,6 +199,9 @@ func (t *FileTOC) Put(buffer []byte) int {
 			next += l.Put(buffer[next:], t.ByteOrder)
 		}
 	}
+	if len(t.trailing.data) > 0 {
+		copy(buffer[t.trailing.offset:], t.trailing.data)
+	}
 	return next
 }
 
@@ -0,0 +1,34 @@
+package macho
+
+// TrailingOffset returns the offset just past the end of the last segment
+// in the file, i.e. the max of Offset+Filesz across all *Segment loads, or
+// LoadSize() if that is larger (as it is when there are no segments).
+// This is where trailing, non-Mach-O data (a zip central directory, an
+// installer payload, ...) starts in a self-extracting binary.
+func (t *FileTOC) TrailingOffset() uint64 {
+	off := uint64(t.LoadSize())
+	for _, l := range t.Loads {
+		if s, ok := l.(*Segment); ok {
+			if m := s.Offset + s.Filesz; m > off {
+				off = m
+			}
+		}
+	}
+	return off
+}
+
+// AppendTrailing records data as a blob to be written by Put/WriteTo just
+// past the last segment, without disturbing any existing load-command
+// offsets, and adds an LC_NOTE load command pointing at {offset, size} so
+// consumers can discover the payload without heuristics.
+func (t *FileTOC) AppendTrailing(data []byte) error {
+	off := t.TrailingOffset()
+	t.trailing.offset = off
+	t.trailing.data = data
+	t.AddLoad(&Note{
+		DataOwner: "go-macho.trailing",
+		Offset:    off,
+		Size:      uint64(len(data)),
+	})
+	return t.Err()
+}
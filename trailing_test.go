@@ -0,0 +1,66 @@
+package macho
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/blacktop/go-macho/types"
+)
+
+func TestTrailingOffsetDefaultsToLoadSizeWithNoSegments(t *testing.T) {
+	tc := &FileTOC{}
+	if got, want := tc.TrailingOffset(), uint64(tc.LoadSize()); got != want {
+		t.Errorf("TrailingOffset() = %d, want %d (LoadSize with no segments)", got, want)
+	}
+}
+
+func TestAppendTrailingIsWrittenByPutAtItsOffset(t *testing.T) {
+	tc := &FileTOC{ByteOrder: binary.LittleEndian}
+	tc.Magic = types.Magic64
+	// A segment ending well past the header and load commands, so the
+	// trailing payload does not land on top of what Put writes for them.
+	tc.AddSegment(&Segment{SegmentHeader: types.SegmentHeader{Name: "__TEXT", Filesz: 0x1000}})
+	data := []byte("a self-extracting payload")
+
+	off := tc.TrailingOffset()
+	if err := tc.AppendTrailing(data); err != nil {
+		t.Fatalf("AppendTrailing() error = %v", err)
+	}
+
+	if got, want := tc.FileSize(), off+uint64(len(data)); got != want {
+		t.Fatalf("FileSize() = %d, want %d", got, want)
+	}
+
+	buf := make([]byte, tc.FileSize())
+	tc.Put(buf)
+	if string(buf[off:]) != string(data) {
+		t.Errorf("Put did not place trailing data at offset %d: got %q, want %q", off, buf[off:], data)
+	}
+}
+
+func TestAppendTrailingAddsLCNote(t *testing.T) {
+	tc := &FileTOC{}
+	data := []byte("a self-extracting payload")
+	off := tc.TrailingOffset()
+
+	if err := tc.AppendTrailing(data); err != nil {
+		t.Fatalf("AppendTrailing() error = %v", err)
+	}
+
+	if len(tc.Loads) != 1 {
+		t.Fatalf("len(Loads) = %d, want 1", len(tc.Loads))
+	}
+	note, ok := tc.Loads[0].(*Note)
+	if !ok {
+		t.Fatalf("Loads[0] is %T, want *Note", tc.Loads[0])
+	}
+	if note.DataOwner != "go-macho.trailing" {
+		t.Errorf("DataOwner = %q, want %q", note.DataOwner, "go-macho.trailing")
+	}
+	if note.Offset != off {
+		t.Errorf("Offset = %d, want %d", note.Offset, off)
+	}
+	if note.Size != uint64(len(data)) {
+		t.Errorf("Size = %d, want %d", note.Size, len(data))
+	}
+}
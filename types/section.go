@@ -0,0 +1,160 @@
+package types
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// zlibMagic is the 4-byte magic Apple's linker (and this package) prepends
+// to a compressed __zdebug_* / __LLVM,__debug_* section, followed by an
+// 8-byte big-endian uncompressed size and then the zlib stream itself.
+const zlibMagic = "ZLIB"
+
+// Section32 is the 32-bit Mach-O on-disk section header.
+type Section32 struct {
+	Name      [16]byte
+	Seg       [16]byte
+	Addr      uint32
+	Size      uint32
+	Offset    uint32
+	Align     uint32
+	Reloff    uint32
+	Nreloc    uint32
+	Flags     uint32
+	Reserved1 uint32
+	Reserved2 uint32
+}
+
+// Section64 is the 64-bit Mach-O on-disk section header.
+type Section64 struct {
+	Name      [16]byte
+	Seg       [16]byte
+	Addr      uint64
+	Size      uint64
+	Offset    uint32
+	Align     uint32
+	Reloff    uint32
+	Nreloc    uint32
+	Flags     uint32
+	Reserved1 uint32
+	Reserved2 uint32
+	Reserved3 uint32
+}
+
+// Section is the parsed, byte-order independent form of a Mach-O section
+// header, together with the bytes backing it.
+type Section struct {
+	Name   string
+	Seg    string
+	Addr   uint64
+	Size   uint64
+	Offset uint32
+	Align  uint32
+	Reloff uint32
+	Nreloc uint32
+	Flags  uint32
+
+	// Compressed marks that Data holds a zlib-compressed payload using the
+	// __zdebug_* convention (zlibMagic + 8-byte big-endian uncompressed
+	// size + zlib stream), rather than the raw, readable section bytes.
+	// FileTOC.Put writes only the section header (Size, Offset, Flags, ...)
+	// and never writes Data; Compressed does not change what Put emits.
+	Compressed bool
+	Data       []byte
+}
+
+func putName(b []byte, name string) {
+	copy(b, name)
+}
+
+// Put32 writes the 32-bit on-disk form of the section header to b and
+// returns the number of bytes written.
+func (s *Section) Put32(b []byte, o binary.ByteOrder) int {
+	putName(b[0:16], s.Name)
+	putName(b[16:32], s.Seg)
+	o.PutUint32(b[32:], uint32(s.Addr))
+	o.PutUint32(b[36:], uint32(s.Size))
+	o.PutUint32(b[40:], s.Offset)
+	o.PutUint32(b[44:], s.Align)
+	o.PutUint32(b[48:], s.Reloff)
+	o.PutUint32(b[52:], s.Nreloc)
+	o.PutUint32(b[56:], s.Flags)
+	o.PutUint32(b[60:], 0)
+	o.PutUint32(b[64:], 0)
+	return 68
+}
+
+// Put64 writes the 64-bit on-disk form of the section header to b and
+// returns the number of bytes written.
+func (s *Section) Put64(b []byte, o binary.ByteOrder) int {
+	putName(b[0:16], s.Name)
+	putName(b[16:32], s.Seg)
+	o.PutUint64(b[32:], s.Addr)
+	o.PutUint64(b[40:], s.Size)
+	o.PutUint32(b[48:], s.Offset)
+	o.PutUint32(b[52:], s.Align)
+	o.PutUint32(b[56:], s.Reloff)
+	o.PutUint32(b[60:], s.Nreloc)
+	o.PutUint32(b[64:], s.Flags)
+	o.PutUint32(b[68:], 0)
+	o.PutUint32(b[72:], 0)
+	o.PutUint32(b[76:], 0)
+	return 80
+}
+
+// Compress replaces Data with raw encoded using the __zdebug_* convention
+// and marks the section Compressed. Size is updated to reflect the
+// compressed length, which is what FileTOC.Put writes into the section
+// header; Put does not write Data itself, and Flags are left unchanged.
+func (s *Section) Compress(raw []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString(zlibMagic)
+	var sz [8]byte
+	binary.BigEndian.PutUint64(sz[:], uint64(len(raw)))
+	buf.Write(sz[:])
+
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return fmt.Errorf("types: compressing section %s: %w", s.Name, err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("types: closing zlib stream for section %s: %w", s.Name, err)
+	}
+
+	s.Data = buf.Bytes()
+	s.Size = uint64(len(s.Data))
+	s.Compressed = true
+	return nil
+}
+
+// Uncompress decodes a __zdebug_*-style compressed section in place,
+// clearing the Compressed flag, so the result can be handed straight to
+// debug/dwarf.
+func (s *Section) Uncompress() error {
+	if !s.Compressed {
+		return nil
+	}
+	if len(s.Data) < 12 || string(s.Data[:4]) != zlibMagic {
+		return fmt.Errorf("types: section %s missing %s magic", s.Name, zlibMagic)
+	}
+
+	uncompressedSize := binary.BigEndian.Uint64(s.Data[4:12])
+	zr, err := zlib.NewReader(bytes.NewReader(s.Data[12:]))
+	if err != nil {
+		return fmt.Errorf("types: opening zlib stream for section %s: %w", s.Name, err)
+	}
+	defer zr.Close()
+
+	out := make([]byte, uncompressedSize)
+	if _, err := io.ReadFull(zr, out); err != nil {
+		return fmt.Errorf("types: decompressing section %s: %w", s.Name, err)
+	}
+
+	s.Data = out
+	s.Size = uncompressedSize
+	s.Compressed = false
+	return nil
+}
@@ -0,0 +1,51 @@
+package types
+
+import "testing"
+
+func TestSectionCompressUncompressRoundTrip(t *testing.T) {
+	raw := []byte("the quick brown fox jumps over the lazy dog, repeatedly, for compressibility")
+	s := &Section{Name: "__debug_info"}
+
+	if err := s.Compress(raw); err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if !s.Compressed {
+		t.Fatal("Compressed = false after Compress")
+	}
+	if string(s.Data[:4]) != zlibMagic {
+		t.Fatalf("Data missing %q magic, got %q", zlibMagic, s.Data[:4])
+	}
+	if s.Size != uint64(len(s.Data)) {
+		t.Errorf("Size = %d, want %d (compressed length)", s.Size, len(s.Data))
+	}
+
+	if err := s.Uncompress(); err != nil {
+		t.Fatalf("Uncompress() error = %v", err)
+	}
+	if s.Compressed {
+		t.Error("Compressed = true after Uncompress")
+	}
+	if string(s.Data) != string(raw) {
+		t.Errorf("Data after round trip = %q, want %q", s.Data, raw)
+	}
+	if s.Size != uint64(len(raw)) {
+		t.Errorf("Size after round trip = %d, want %d", s.Size, len(raw))
+	}
+}
+
+func TestSectionUncompressNoOpWhenNotCompressed(t *testing.T) {
+	s := &Section{Name: "__text", Data: []byte("not compressed"), Compressed: false}
+	if err := s.Uncompress(); err != nil {
+		t.Fatalf("Uncompress() on uncompressed section error = %v", err)
+	}
+	if string(s.Data) != "not compressed" {
+		t.Errorf("Data = %q, want unchanged", s.Data)
+	}
+}
+
+func TestSectionUncompressRejectsBadMagic(t *testing.T) {
+	s := &Section{Name: "__debug_info", Data: []byte("not zlib data at all"), Compressed: true}
+	if err := s.Uncompress(); err == nil {
+		t.Error("Uncompress() = nil, want error for missing zlib magic")
+	}
+}
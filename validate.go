@@ -0,0 +1,61 @@
+package macho
+
+import "fmt"
+
+// Validate sanity-checks t against the limits the Go linker's own Mach-O
+// loader applies when deciding whether a header is even plausible: an
+// implausibly large NCommands or SizeCommands almost always means the file
+// (or a TOC built by hand) is corrupt rather than merely unusual. It also
+// checks that every segment's Firstsect/Nsect stay within t.Sections, and
+// that the computed TOCSize doesn't overlap the Offset of any segment;
+// __PAGEZERO and segments at Offset 0 (such as __TEXT, which contains the
+// header itself) are exempt from that overlap check.
+//
+// MaxLoadCommands and MaxSizeCommands default to DefaultMaxLoadCommands and
+// DefaultMaxSizeCommands when zero, so callers that never touch the fields
+// get the stock limits for free.
+func (t *FileTOC) Validate() error {
+	maxLoads := t.MaxLoadCommands
+	if maxLoads == 0 {
+		maxLoads = DefaultMaxLoadCommands
+	}
+	maxSize := t.MaxSizeCommands
+	if maxSize == 0 {
+		maxSize = DefaultMaxSizeCommands
+	}
+
+	if t.NCommands > maxLoads {
+		return fmt.Errorf("macho: NCommands %d exceeds limit %d", t.NCommands, maxLoads)
+	}
+	if t.SizeCommands > maxSize {
+		return fmt.Errorf("macho: SizeCommands %d exceeds limit %d", t.SizeCommands, maxSize)
+	}
+
+	for _, l := range t.Loads {
+		seg, ok := l.(*Segment)
+		if !ok {
+			continue
+		}
+		if uint64(seg.Firstsect)+uint64(seg.Nsect) > uint64(len(t.Sections)) {
+			return fmt.Errorf("macho: segment %s claims sections [%d:%d), but only %d sections exist", seg.Name, seg.Firstsect, seg.Firstsect+seg.Nsect, len(t.Sections))
+		}
+	}
+
+	// TOCSize is only computed once a segment needs the overlap check, so a
+	// TOC without such segments validates without consulting its magic.
+	var toc uint32
+	for _, l := range t.Loads {
+		seg, ok := l.(*Segment)
+		if !ok || seg.Name == "__PAGEZERO" {
+			continue
+		}
+		if seg.Offset != 0 && toc == 0 {
+			toc = t.TOCSize()
+		}
+		if seg.Offset != 0 && seg.Offset < uint64(toc) {
+			return fmt.Errorf("macho: TOCSize %d overlaps segment %s at offset %d", toc, seg.Name, seg.Offset)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package macho
+
+import "testing"
+
+func TestValidateDefaultsLimitsWhenZero(t *testing.T) {
+	tc := &FileTOC{} // zero MaxLoadCommands/MaxSizeCommands
+	tc.NCommands = DefaultMaxLoadCommands + 1
+	if err := tc.Validate(); err == nil {
+		t.Error("Validate() = nil, want error for NCommands exceeding DefaultMaxLoadCommands")
+	}
+}
+
+func TestValidateRespectsCustomLimits(t *testing.T) {
+	tc := &FileTOC{MaxLoadCommands: 2}
+	tc.NCommands = 3
+	if err := tc.Validate(); err == nil {
+		t.Error("Validate() = nil, want error for NCommands exceeding custom MaxLoadCommands")
+	}
+
+	tc.NCommands = 2
+	if err := tc.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil for NCommands within custom MaxLoadCommands", err)
+	}
+}
+
+func TestValidateSizeCommandsLimit(t *testing.T) {
+	tc := &FileTOC{MaxSizeCommands: 10}
+	tc.SizeCommands = 11
+	if err := tc.Validate(); err == nil {
+		t.Error("Validate() = nil, want error for SizeCommands exceeding MaxSizeCommands")
+	}
+}
@@ -0,0 +1,21 @@
+package macho
+
+import "github.com/blacktop/go-macho/types"
+
+// segmentCommandSize returns the on-disk size of an LC_SEGMENT/LC_SEGMENT_64
+// load command, not including its trailing section headers.
+func segmentCommandSize(magic types.Magic) uint32 {
+	if magic == types.Magic64 {
+		return 72
+	}
+	return 56
+}
+
+// sectionWireSize returns the on-disk size of a single section/section_64
+// header, matching types.Section.Put32/Put64.
+func sectionWireSize(magic types.Magic) uint32 {
+	if magic == types.Magic64 {
+		return 80
+	}
+	return 68
+}
@@ -0,0 +1,25 @@
+package macho
+
+import (
+	"testing"
+
+	"github.com/blacktop/go-macho/types"
+)
+
+func TestSegmentCommandSize(t *testing.T) {
+	if got := segmentCommandSize(types.Magic64); got != 72 {
+		t.Errorf("segmentCommandSize(Magic64) = %d, want 72", got)
+	}
+	if got := segmentCommandSize(types.Magic32); got != 56 {
+		t.Errorf("segmentCommandSize(Magic32) = %d, want 56", got)
+	}
+}
+
+func TestSectionWireSize(t *testing.T) {
+	if got := sectionWireSize(types.Magic64); got != 80 {
+		t.Errorf("sectionWireSize(Magic64) = %d, want 80", got)
+	}
+	if got := sectionWireSize(types.Magic32); got != 68 {
+		t.Errorf("sectionWireSize(Magic32) = %d, want 68", got)
+	}
+}